@@ -0,0 +1,123 @@
+package preimages
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/types"
+)
+
+// stepResult is what GetStepData caches locally per (ref, pos): the prestate and proof
+// data, plus enough of a derived preimage's metadata to reconstruct it from the beacon
+// without calling back into the wrapped accessor.
+type stepResult struct {
+	prestate  []byte
+	proofData []byte
+
+	// oracleKey is nil if this step produced no preimage data.
+	oracleKey    []byte
+	localContext common.Hash
+	oracleOffset uint32
+}
+
+// CachingTraceAccessor wraps a types.TraceAccessor, consulting a types.PreimageBeacon for
+// a position's preimage before falling back to the wrapped accessor, and publishing every
+// preimage it derives so other game actors sharing the beacon (e.g. the oracle-posting
+// loop) can reuse it instead of re-deriving or polling for it themselves.
+type CachingTraceAccessor struct {
+	inner  types.TraceAccessor
+	beacon types.PreimageBeacon
+
+	mu      sync.Mutex
+	results map[string]stepResult // position cache key -> the last GetStepData result for it
+}
+
+var _ types.TraceAccessor = (*CachingTraceAccessor)(nil)
+
+// NewCachingTraceAccessor creates a new [CachingTraceAccessor] instance.
+func NewCachingTraceAccessor(inner types.TraceAccessor, beacon types.PreimageBeacon) *CachingTraceAccessor {
+	return &CachingTraceAccessor{
+		inner:   inner,
+		beacon:  beacon,
+		results: make(map[string]stepResult),
+	}
+}
+
+// NewOracleSharingAccessor builds a new Cache bounded to maxEntries that is restored from
+// persistDir if it holds a prior run's preimages, and a CachingTraceAccessor wrapping inner
+// against that cache. Each call creates its own Cache; accessors built by separate calls do
+// not share one. persistDir may be empty to skip restoring from disk. Nothing is written to
+// persistDir here; the returned Cache writes to disk only when its Persist is called.
+func NewOracleSharingAccessor(inner types.TraceAccessor, maxEntries int, persistDir string) (*CachingTraceAccessor, *Cache, error) {
+	cache := NewCache(maxEntries)
+	if persistDir != "" {
+		if err := cache.Restore(persistDir); err != nil {
+			return nil, nil, err
+		}
+	}
+	return NewCachingTraceAccessor(inner, cache), cache, nil
+}
+
+// Get delegates to the wrapped accessor. Only preimage data is cached.
+func (c *CachingTraceAccessor) Get(ctx context.Context, game types.Game, ref types.Claim, pos types.Position) (common.Hash, error) {
+	return c.inner.Get(ctx, game, ref, pos)
+}
+
+// GetStepData(ref, pos) is deterministic for a given provider, so once this position has
+// been resolved once, GetStepData serves it out of the local cache and the beacon without
+// calling the wrapped accessor again. This is what lets a cannon/asterisc-backed provider's
+// one-time-per-position VM execution actually be skipped by every later caller of this
+// accessor, rather than merely having its output bytes swapped out after the expensive
+// derivation has already happened again. The prestate and proof data are cached per
+// CachingTraceAccessor instance, so another instance sharing the same beacon still calls its
+// own wrapped accessor the first time it sees a position.
+//
+// The wrapped accessor is only called on a true miss: the first time this instance sees a
+// position, or after the beacon has lost the preimage for a previously-seen position from
+// both memory and disk, in which case it must be re-derived.
+func (c *CachingTraceAccessor) GetStepData(ctx context.Context, game types.Game, ref types.Claim, pos types.Position) ([]byte, []byte, *types.PreimageOracleData, error) {
+	posKey := stepCacheKey(ref, pos)
+
+	c.mu.Lock()
+	cached, ok := c.results[posKey]
+	c.mu.Unlock()
+
+	if ok {
+		if cached.oracleKey == nil {
+			// This step never produced a preimage; there is nothing to re-derive.
+			return cached.prestate, cached.proofData, nil, nil
+		}
+		if data, hit := c.beacon.LookupPreimage(cached.oracleKey); hit {
+			preimageData := types.NewPreimageOracleData(cached.localContext, cached.oracleKey, data, cached.oracleOffset)
+			return cached.prestate, cached.proofData, preimageData, nil
+		}
+		// Fall through: the beacon no longer has this preimage anywhere, so it must be
+		// re-derived from the wrapped accessor.
+	}
+
+	prestate, proofData, preimageData, err := c.inner.GetStepData(ctx, game, ref, pos)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	next := stepResult{prestate: prestate, proofData: proofData}
+	if preimageData != nil {
+		c.beacon.AddPreimage(preimageData)
+		next.oracleKey = preimageData.OracleKey
+		next.localContext = preimageData.LocalContext
+		next.oracleOffset = preimageData.OracleOffset
+	}
+	c.mu.Lock()
+	c.results[posKey] = next
+	c.mu.Unlock()
+
+	return prestate, proofData, preimageData, nil
+}
+
+// stepCacheKey identifies a (ref, pos) pair for the purpose of caching its GetStepData
+// result.
+func stepCacheKey(ref types.Claim, pos types.Position) string {
+	return ref.Value.Hex() + "/" + pos.ToGIndex().String()
+}
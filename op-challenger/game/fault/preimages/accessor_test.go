@@ -0,0 +1,128 @@
+package preimages
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/types"
+)
+
+// fakeAccessor is a types.TraceAccessor that counts how many times GetStepData is called,
+// so tests can assert a cache hit skips it rather than merely swapping out its output.
+type fakeAccessor struct {
+	calls        int
+	prestate     []byte
+	proofData    []byte
+	preimageData *types.PreimageOracleData
+}
+
+func (f *fakeAccessor) Get(ctx context.Context, game types.Game, ref types.Claim, pos types.Position) (common.Hash, error) {
+	return common.Hash{}, nil
+}
+
+func (f *fakeAccessor) GetStepData(ctx context.Context, game types.Game, ref types.Claim, pos types.Position) ([]byte, []byte, *types.PreimageOracleData, error) {
+	f.calls++
+	return f.prestate, f.proofData, f.preimageData, nil
+}
+
+var _ types.TraceAccessor = (*fakeAccessor)(nil)
+
+func TestCachingTraceAccessor_CacheHitSkipsWrappedAccessor(t *testing.T) {
+	preimage := types.NewPreimageOracleData(common.Hash{}, []byte{1}, []byte("large preimage"), 3)
+	inner := &fakeAccessor{prestate: []byte("prestate"), proofData: []byte("proof"), preimageData: preimage}
+	accessor := NewCachingTraceAccessor(inner, NewCache(10))
+
+	ref := types.Claim{}
+	pos := types.Position{}
+
+	if _, _, _, err := accessor.GetStepData(context.Background(), nil, ref, pos); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inner.calls != 1 {
+		t.Fatalf("got %d calls deriving the first time, want 1", inner.calls)
+	}
+
+	prestate, proofData, gotPreimage, err := accessor.GetStepData(context.Background(), nil, ref, pos)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inner.calls != 1 {
+		t.Fatalf("expected the repeat visit to be served from the cache without calling the wrapped accessor, got %d calls", inner.calls)
+	}
+	if string(prestate) != "prestate" || string(proofData) != "proof" {
+		t.Fatalf("got prestate=%q proofData=%q, want the cached values", prestate, proofData)
+	}
+	if string(gotPreimage.OracleData) != "large preimage" || gotPreimage.OracleOffset != 3 {
+		t.Fatalf("got preimage %+v, want the cached preimage data", gotPreimage)
+	}
+}
+
+func TestCachingTraceAccessor_StepWithoutPreimageIsAlsoCached(t *testing.T) {
+	inner := &fakeAccessor{prestate: []byte("prestate"), proofData: []byte("proof")}
+	accessor := NewCachingTraceAccessor(inner, NewCache(10))
+
+	ref := types.Claim{}
+	pos := types.Position{}
+
+	for i := 0; i < 2; i++ {
+		_, _, preimageData, err := accessor.GetStepData(context.Background(), nil, ref, pos)
+		if err != nil {
+			t.Fatalf("call %d: unexpected error: %v", i, err)
+		}
+		if preimageData != nil {
+			t.Fatalf("call %d: expected no preimage data", i)
+		}
+	}
+	if inner.calls != 1 {
+		t.Fatalf("got %d calls, want 1", inner.calls)
+	}
+}
+
+func TestNewOracleSharingAccessor_RestoresFromPersistDir(t *testing.T) {
+	dir := t.TempDir()
+
+	seed := NewCache(10)
+	seed.AddPreimage(types.NewPreimageOracleData(common.Hash{}, []byte{1}, []byte("large preimage"), 0))
+	if err := seed.Persist(dir); err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+
+	inner := &fakeAccessor{prestate: []byte("prestate"), proofData: []byte("proof")}
+	accessor, cache, err := NewOracleSharingAccessor(inner, 10, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := cache.LookupPreimage([]byte{1}); !ok {
+		t.Fatalf("expected the cache to be restored from a prior run's persisted preimages")
+	}
+	if accessor == nil {
+		t.Fatalf("expected a non-nil accessor")
+	}
+}
+
+func TestCachingTraceAccessor_RereDerivesAfterBeaconFullyEvictsEntry(t *testing.T) {
+	preimage := types.NewPreimageOracleData(common.Hash{}, []byte{1}, []byte("large preimage"), 0)
+	inner := &fakeAccessor{prestate: []byte("prestate"), proofData: []byte("proof"), preimageData: preimage}
+	beacon := NewCache(10)
+	accessor := NewCachingTraceAccessor(inner, beacon)
+
+	ref := types.Claim{}
+	pos := types.Position{}
+
+	if _, _, _, err := accessor.GetStepData(context.Background(), nil, ref, pos); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Simulate the beacon having evicted the entry (e.g. memory pressure with no persisted
+	// copy on disk) out from under the accessor's own local index.
+	delete(beacon.entries, "01")
+
+	if _, _, _, err := accessor.GetStepData(context.Background(), nil, ref, pos); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inner.calls != 2 {
+		t.Fatalf("got %d calls, want 2 (re-derived after the beacon lost the entry)", inner.calls)
+	}
+}
@@ -0,0 +1,289 @@
+// Package preimages provides a concrete types.PreimageBeacon implementation that caches
+// preimage oracle data derived from a TraceProvider so it can be shared across game actors
+// and survive a challenger restart.
+package preimages
+
+import (
+	"container/list"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/types"
+)
+
+// defaultMaxEntries bounds the cache size when the caller does not specify one.
+const defaultMaxEntries = 2048
+
+// entryHeaderLen is the size in bytes of an encoded entry's metadata: a 1 byte isLocal
+// flag, a 32 byte localContext and a 4 byte big-endian offset, ahead of the raw data.
+const entryHeaderLen = 1 + common.HashLength + 4
+
+// entry is the cached representation of a single preimage oracle value.
+type entry struct {
+	key          []byte
+	data         []byte
+	offset       uint32
+	isLocal      bool
+	localContext common.Hash
+	elem         *list.Element
+}
+
+// encode serializes e's metadata and data for persistence to disk.
+func (e *entry) encode() []byte {
+	buf := make([]byte, entryHeaderLen, entryHeaderLen+len(e.data))
+	if e.isLocal {
+		buf[0] = 1
+	}
+	copy(buf[1:1+common.HashLength], e.localContext.Bytes())
+	binary.BigEndian.PutUint32(buf[1+common.HashLength:entryHeaderLen], e.offset)
+	return append(buf, e.data...)
+}
+
+// decodeEntry is the inverse of entry.encode, reconstructing the entry cached under key
+// from its persisted bytes.
+func decodeEntry(key []byte, raw []byte) (*entry, error) {
+	if len(raw) < entryHeaderLen {
+		return nil, fmt.Errorf("truncated preimage cache entry (%v bytes)", len(raw))
+	}
+	return &entry{
+		key:          key,
+		isLocal:      raw[0] == 1,
+		localContext: common.BytesToHash(raw[1 : 1+common.HashLength]),
+		offset:       binary.BigEndian.Uint32(raw[1+common.HashLength : entryHeaderLen]),
+		data:         raw[entryHeaderLen:],
+	}, nil
+}
+
+// Cache is a concurrency-safe, size-bounded types.PreimageBeacon. Entries are evicted in
+// least-recently-used order once the cache grows beyond maxEntries, but an entry with a
+// live subscriber is never evicted out from under it.
+type Cache struct {
+	mu           sync.Mutex
+	maxEntries   int
+	entries      map[string]*entry
+	evictionList *list.List // Front is most recently used. Values are map keys (string).
+	subscribers  map[string][]chan *types.PreimageOracleData
+
+	// persistDir is set by Persist/Restore and used by LookupPreimage to lazily load an
+	// entry that has been evicted from memory but not from disk.
+	persistDir string
+}
+
+var _ types.PreimageBeacon = (*Cache)(nil)
+
+// NewCache creates a new [Cache] bounded to hold at most maxEntries preimages.
+// A maxEntries of 0 or less uses defaultMaxEntries.
+func NewCache(maxEntries int) *Cache {
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxEntries
+	}
+	return &Cache{
+		maxEntries:   maxEntries,
+		entries:      make(map[string]*entry),
+		evictionList: list.New(),
+		subscribers:  make(map[string][]chan *types.PreimageOracleData),
+	}
+}
+
+// LookupPreimage implements types.PreimageBeacon. On an in-memory miss it falls back to
+// loading the entry from the directory passed to Persist/Restore, if any, so a preimage
+// evicted from memory since the last restart is not treated as permanently lost.
+func (c *Cache) LookupPreimage(key []byte) ([]byte, bool) {
+	k := hex.EncodeToString(key)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[k]; ok {
+		c.evictionList.MoveToFront(e.elem)
+		return e.data, true
+	}
+	if c.persistDir == "" {
+		return nil, false
+	}
+	raw, err := os.ReadFile(filepath.Join(c.persistDir, k))
+	if err != nil {
+		return nil, false
+	}
+	e, err := decodeEntry(key, raw)
+	if err != nil {
+		return nil, false
+	}
+	e.elem = c.evictionList.PushFront(k)
+	c.entries[k] = e
+	c.evict()
+	return e.data, true
+}
+
+// AddPreimage implements types.PreimageBeacon. The notification to subscribers happens
+// while still holding c.mu, rather than after releasing it, so a concurrent cancel (which
+// also takes c.mu before closing its channel) can never close a channel out from under a
+// send in progress here.
+func (c *Cache) AddPreimage(data *types.PreimageOracleData) {
+	k := hex.EncodeToString(data.OracleKey)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[k]; ok {
+		e.data = data.OracleData
+		c.evictionList.MoveToFront(e.elem)
+	} else {
+		e := &entry{
+			key:          data.OracleKey,
+			data:         data.OracleData,
+			offset:       data.OracleOffset,
+			isLocal:      data.IsLocal,
+			localContext: data.LocalContext,
+		}
+		e.elem = c.evictionList.PushFront(k)
+		c.entries[k] = e
+		c.evict()
+	}
+
+	for _, ch := range c.subscribers[k] {
+		select {
+		case ch <- data:
+		default:
+			// Subscriber isn't keeping up; drop the update rather than block the publisher.
+		}
+	}
+}
+
+// evict removes least-recently-used entries until the cache is within maxEntries, skipping
+// any entry that still has a live subscriber and the front entry, which is the one the
+// caller has just inserted. Callers must hold c.mu.
+func (c *Cache) evict() {
+	for c.evictionList.Len() > c.maxEntries {
+		removed := false
+		for el := c.evictionList.Back(); el != nil && el != c.evictionList.Front(); el = el.Prev() {
+			k := el.Value.(string)
+			if len(c.subscribers[k]) > 0 {
+				continue
+			}
+			c.evictionList.Remove(el)
+			delete(c.entries, k)
+			removed = true
+			break
+		}
+		if !removed {
+			// Every remaining entry but the newest has a live subscriber; refuse to evict further.
+			return
+		}
+	}
+}
+
+// SubscribeUpdates implements types.PreimageBeacon.
+func (c *Cache) SubscribeUpdates(key []byte) (<-chan *types.PreimageOracleData, types.CancelFunc) {
+	k := hex.EncodeToString(key)
+	ch := make(chan *types.PreimageOracleData, 1)
+
+	c.mu.Lock()
+	c.subscribers[k] = append(c.subscribers[k], ch)
+	c.mu.Unlock()
+
+	cancel := func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		subs := c.subscribers[k]
+		for i, existing := range subs {
+			if existing == ch {
+				c.subscribers[k] = append(subs[:i], subs[i+1:]...)
+				break
+			}
+		}
+		if len(c.subscribers[k]) == 0 {
+			delete(c.subscribers, k)
+		}
+		close(ch)
+	}
+	return ch, cancel
+}
+
+// Persist writes every cached preimage, with its metadata, to dir as a flat file named by
+// the hex-encoded oracle key, plus an index file listing them, so Restore can rebuild the
+// cache without re-deriving the preimages from the trace provider after a challenger
+// restart. It also remembers dir so LookupPreimage can lazily load an entry that is
+// subsequently evicted from memory.
+func (c *Cache) Persist(dir string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create preimage cache dir: %w", err)
+	}
+	index := make([]byte, 0, len(c.entries)*65)
+	for k, e := range c.entries {
+		if err := os.WriteFile(filepath.Join(dir, k), e.encode(), 0o644); err != nil {
+			return fmt.Errorf("persist preimage %v: %w", k, err)
+		}
+		index = append(index, k...)
+		index = append(index, '\n')
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index"), index, 0o644); err != nil {
+		return fmt.Errorf("persist preimage cache index: %w", err)
+	}
+	c.persistDir = dir
+	return nil
+}
+
+// Restore loads preimages previously written to dir by Persist back into the cache,
+// respecting maxEntries, and remembers dir so LookupPreimage can lazily load any entry not
+// loaded here. It is safe to call on a cache that already has entries in place; existing
+// entries take precedence over the persisted copy. A missing dir is not an error.
+func (c *Cache) Restore(dir string) error {
+	index, err := os.ReadFile(filepath.Join(dir, "index"))
+	if errors.Is(err, os.ErrNotExist) {
+		c.mu.Lock()
+		c.persistDir = dir
+		c.mu.Unlock()
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("read preimage cache index: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.persistDir = dir
+	for _, k := range splitLines(index) {
+		if _, ok := c.entries[k]; ok {
+			continue
+		}
+		key, err := hex.DecodeString(k)
+		if err != nil {
+			return fmt.Errorf("invalid preimage cache entry %v: %w", k, err)
+		}
+		raw, err := os.ReadFile(filepath.Join(dir, k))
+		if err != nil {
+			return fmt.Errorf("restore preimage %v: %w", k, err)
+		}
+		e, err := decodeEntry(key, raw)
+		if err != nil {
+			return fmt.Errorf("restore preimage %v: %w", k, err)
+		}
+		e.elem = c.evictionList.PushFront(k)
+		c.entries[k] = e
+		c.evict()
+	}
+	return nil
+}
+
+// splitLines splits a newline-delimited index file into its non-empty entries.
+func splitLines(b []byte) []string {
+	var lines []string
+	start := 0
+	for i, c := range b {
+		if c == '\n' {
+			if i > start {
+				lines = append(lines, string(b[start:i]))
+			}
+			start = i + 1
+		}
+	}
+	return lines
+}
@@ -0,0 +1,146 @@
+package preimages
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/ethereum-optimism/optimism/op-challenger/game/fault/types"
+)
+
+func oracleData(key byte, data string) *types.PreimageOracleData {
+	return types.NewPreimageOracleData(common.Hash{}, []byte{key}, []byte(data), 0)
+}
+
+func TestCache_AddAndLookup(t *testing.T) {
+	c := NewCache(10)
+	data := oracleData(1, "hello world")
+	c.AddPreimage(data)
+
+	got, ok := c.LookupPreimage(data.OracleKey)
+	if !ok {
+		t.Fatalf("expected preimage to be found")
+	}
+	if string(got) != "hello world" {
+		t.Fatalf("got %q, want %q", got, "hello world")
+	}
+
+	if _, ok := c.LookupPreimage([]byte{0xff}); ok {
+		t.Fatalf("expected lookup of unknown key to miss")
+	}
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewCache(2)
+	c.AddPreimage(oracleData(1, "a"))
+	c.AddPreimage(oracleData(2, "b"))
+
+	// Touch key 1 so key 2 becomes the least recently used entry.
+	if _, ok := c.LookupPreimage([]byte{1}); !ok {
+		t.Fatalf("expected key 1 to be cached")
+	}
+	c.AddPreimage(oracleData(3, "c"))
+
+	if _, ok := c.LookupPreimage([]byte{2}); ok {
+		t.Fatalf("expected key 2 to have been evicted")
+	}
+	if _, ok := c.LookupPreimage([]byte{1}); !ok {
+		t.Fatalf("expected key 1 to still be cached")
+	}
+	if _, ok := c.LookupPreimage([]byte{3}); !ok {
+		t.Fatalf("expected key 3 to be cached")
+	}
+}
+
+func TestCache_SubscriberPinsEntryAgainstEviction(t *testing.T) {
+	c := NewCache(1)
+	c.AddPreimage(oracleData(1, "a"))
+
+	_, cancel := c.SubscribeUpdates([]byte{1})
+	defer cancel()
+
+	// Adding a second entry would normally evict key 1, but it has a live subscriber.
+	c.AddPreimage(oracleData(2, "b"))
+
+	if _, ok := c.LookupPreimage([]byte{1}); !ok {
+		t.Fatalf("expected subscribed key 1 to survive eviction")
+	}
+	if _, ok := c.LookupPreimage([]byte{2}); !ok {
+		t.Fatalf("expected key 2 to be cached")
+	}
+}
+
+func TestCache_SubscribeUpdatesReceivesAndCancel(t *testing.T) {
+	c := NewCache(10)
+	updates, cancel := c.SubscribeUpdates([]byte{1})
+
+	data := oracleData(1, "a")
+	c.AddPreimage(data)
+
+	select {
+	case got := <-updates:
+		if string(got.OracleData) != "a" {
+			t.Fatalf("got %q, want %q", got.OracleData, "a")
+		}
+	default:
+		t.Fatalf("expected a buffered update to be immediately available")
+	}
+
+	cancel()
+	if _, open := <-updates; open {
+		t.Fatalf("expected channel to be closed after cancel")
+	}
+}
+
+func TestCache_PersistAndRestore(t *testing.T) {
+	dir := t.TempDir()
+
+	c := NewCache(10)
+	data := types.NewPreimageOracleData(common.Hash{1}, []byte{1}, []byte("large preimage"), 7)
+	c.AddPreimage(data)
+	if err := c.Persist(dir); err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+
+	restored := NewCache(10)
+	if err := restored.Restore(dir); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	got, ok := restored.LookupPreimage([]byte{1})
+	if !ok {
+		t.Fatalf("expected restored cache to contain the persisted preimage")
+	}
+	if string(got) != "large preimage" {
+		t.Fatalf("got %q, want %q", got, "large preimage")
+	}
+
+	e := restored.entries[restored.evictionList.Front().Value.(string)]
+	if e.offset != 7 || e.localContext != (common.Hash{1}) {
+		t.Fatalf("expected restored entry metadata to round-trip, got offset=%d localContext=%v", e.offset, e.localContext)
+	}
+}
+
+func TestCache_LookupLazilyLoadsEvictedEntryFromDisk(t *testing.T) {
+	dir := t.TempDir()
+
+	c := NewCache(1)
+	c.AddPreimage(oracleData(1, "a"))
+	if err := c.Persist(dir); err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+
+	// Evict key 1 from memory without removing it from dir.
+	c.AddPreimage(oracleData(2, "b"))
+	if _, ok := c.entries[hex.EncodeToString([]byte{1})]; ok {
+		t.Fatalf("expected key 1 to have been evicted from memory")
+	}
+
+	got, ok := c.LookupPreimage([]byte{1})
+	if !ok {
+		t.Fatalf("expected LookupPreimage to lazily reload the evicted entry from disk")
+	}
+	if string(got) != "a" {
+		t.Fatalf("got %q, want %q", got, "a")
+	}
+}
@@ -0,0 +1,190 @@
+package types
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// stubProvider is a TraceProvider that answers Get/GetStepData from fixed values. It does
+// not implement SessionTraceProvider, so StartSessionFor falls back to calling it directly.
+type stubProvider struct {
+	value common.Hash
+}
+
+func (s *stubProvider) Get(ctx context.Context, i Position) (common.Hash, error) {
+	return s.value, nil
+}
+
+func (s *stubProvider) GetStepData(ctx context.Context, i Position) ([]byte, []byte, *PreimageOracleData, error) {
+	return []byte("prestate"), []byte("proof"), nil, nil
+}
+
+func (s *stubProvider) AbsolutePreStateCommitment(ctx context.Context) (common.Hash, error) {
+	return common.Hash{}, nil
+}
+
+var _ TraceProvider = (*stubProvider)(nil)
+
+// sessionProvider additionally implements SessionTraceProvider, tracking whether a session
+// is already running the same way a real cannon/asterisc provider would.
+type sessionProvider struct {
+	stubProvider
+	running atomic.Bool
+}
+
+func (s *sessionProvider) StartSession(ctx context.Context, concurrency int) (*TraceSession, error) {
+	return NewTraceSession(ctx, concurrency, &s.running, func(ctx context.Context, req TraceRequest) TraceResult {
+		value, err := s.Get(ctx, req.Pos)
+		return TraceResult{Value: value, Err: err}
+	})
+}
+
+var _ SessionTraceProvider = (*sessionProvider)(nil)
+
+func TestTraceSession_SingleSessionGuard(t *testing.T) {
+	p := &sessionProvider{stubProvider: stubProvider{value: common.Hash{1}}}
+
+	s1, err := p.StartSession(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("unexpected error starting first session: %v", err)
+	}
+
+	if _, err := p.StartSession(context.Background(), 2); err != ErrSessionAlreadyRunning {
+		t.Fatalf("got err %v, want ErrSessionAlreadyRunning", err)
+	}
+
+	s1.Close()
+
+	s2, err := p.StartSession(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("unexpected error starting session after Close: %v", err)
+	}
+	s2.Close()
+}
+
+func TestTraceSession_SubmitAfterCloseReturnsErrorNotPanic(t *testing.T) {
+	p := &sessionProvider{stubProvider: stubProvider{value: common.Hash{1}}}
+	s, err := p.StartSession(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s.Close()
+
+	if _, err := s.Submit(TraceRequest{}); err != ErrSessionClosed {
+		t.Fatalf("got err %v, want ErrSessionClosed", err)
+	}
+}
+
+func TestTraceSession_CloseIsIdempotent(t *testing.T) {
+	p := &sessionProvider{stubProvider: stubProvider{value: common.Hash{1}}}
+	s, err := p.StartSession(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s.Close()
+	s.Close() // must not panic on double-close
+}
+
+func TestGetMany_FansOutAndCollectsResults(t *testing.T) {
+	p := &stubProvider{value: common.Hash{9}}
+
+	results, err := GetMany(context.Background(), p, 4, []TraceRequest{
+		{Pos: Position{}}, {Pos: Position{}}, {Pos: Position{}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	for i, r := range results {
+		if r.Err != nil {
+			t.Fatalf("result %d: unexpected error %v", i, r.Err)
+		}
+		if r.Value != (common.Hash{9}) {
+			t.Fatalf("result %d: got %v, want %v", i, r.Value, common.Hash{9})
+		}
+	}
+}
+
+func TestTraceSession_SubmitAfterContextCancelledReturnsErrorNotDeadlock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var running atomic.Bool
+	s, err := NewTraceSession(ctx, 1, &running, func(ctx context.Context, req TraceRequest) TraceResult {
+		return TraceResult{}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer s.Close()
+
+	// Cancel ctx without calling Close, the way a caller's own ctx might expire mid-tick.
+	// Every worker exits on ctx.Done(), so without its own ctx.Done() case Submit would
+	// block forever trying to hand a job to a worker that is never coming back.
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := s.Submit(TraceRequest{}); err != context.Canceled {
+			t.Errorf("got err %v, want context.Canceled", err)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Submit did not return promptly after ctx was cancelled")
+	}
+}
+
+func TestGetMany_ContextCancelledMidTickDoesNotDeadlock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &stubProvider{value: common.Hash{9}}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := GetMany(ctx, p, 2, []TraceRequest{{Pos: Position{}}}); err != context.Canceled {
+			t.Errorf("got err %v, want context.Canceled", err)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("GetMany did not return promptly after ctx was cancelled")
+	}
+}
+
+func TestTraceSession_WorkerStopsOnContextCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var running atomic.Bool
+	s, err := NewTraceSession(ctx, 1, &running, func(ctx context.Context, req TraceRequest) TraceResult {
+		return TraceResult{}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Cancelling ctx, rather than calling Close, should still make the idle worker exit so
+	// Close (called by the caller's normal teardown) returns promptly.
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("expected Close to return promptly once ctx is cancelled")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"sync"
+	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -12,6 +14,14 @@ import (
 var (
 	ErrGameDepthReached = errors.New("game depth reached")
 
+	// ErrSessionAlreadyRunning is returned by SessionTraceProvider.StartSession when a session for
+	// that provider is already active. Only one concurrent session per provider is supported.
+	ErrSessionAlreadyRunning = errors.New("trace session already running")
+
+	// ErrSessionClosed is returned by TraceSession.Submit once the session has been, or is
+	// concurrently being, closed.
+	ErrSessionClosed = errors.New("trace session closed")
+
 	// NoLocalContext is the LocalContext value used when the cannon trace provider is used alone instead of as part
 	// of a split game.
 	NoLocalContext = common.Hash{}
@@ -100,6 +110,30 @@ func NewPreimageOracleData(lctx common.Hash, key []byte, data []byte, offset uin
 	}
 }
 
+// CancelFunc cancels a subscription registered with PreimageBeacon.SubscribeUpdates.
+type CancelFunc func()
+
+// PreimageBeacon is a shared store of preimage oracle data. It lets multiple game actors
+// (honest actor, responder, bond claimer) running in the same process reuse preimages that
+// are expensive to re-derive from the cannon/asterisc trace, and lets consumers react to a
+// preimage becoming available instead of polling for it.
+type PreimageBeacon interface {
+	// LookupPreimage returns the data previously recorded for key via AddPreimage.
+	// ok is false if no data has been recorded for key, or if a size-bounded implementation
+	// has since evicted it.
+	LookupPreimage(key []byte) (data []byte, ok bool)
+
+	// AddPreimage records data for later lookup via LookupPreimage and notifies any
+	// subscribers registered for its key via SubscribeUpdates.
+	AddPreimage(data *PreimageOracleData)
+
+	// SubscribeUpdates registers for notification when data for key is recorded via
+	// AddPreimage. The returned channel receives updates published for key until cancel is
+	// called, at which point it is closed. An implementation may drop an update instead of
+	// blocking the publisher when the subscriber has not yet received the previous one.
+	SubscribeUpdates(key []byte) (updates <-chan *PreimageOracleData, cancel CancelFunc)
+}
+
 // StepCallData encapsulates the data needed to perform a step.
 type StepCallData struct {
 	ClaimIndex uint64
@@ -136,6 +170,194 @@ type TraceProvider interface {
 	AbsolutePreStateCommitment(ctx context.Context) (hash common.Hash, err error)
 }
 
+// SessionTraceProvider is implemented by TraceProvider implementations that can multiplex
+// Get/GetStepData over an internal worker pool instead of answering each one in-line. It is
+// a separate interface from TraceProvider, rather than an additional method on it, so that
+// existing TraceProvider implementations that have no use for session-based concurrency
+// (e.g. the alphabet and output-root providers) keep satisfying TraceProvider unmodified.
+// Use StartSessionFor to get a session from any TraceProvider, with or without this.
+type SessionTraceProvider interface {
+	TraceProvider
+
+	// StartSession returns a TraceSession that multiplexes Get and GetStepData over an
+	// internal worker pool of the given concurrency, so a caller can submit many position
+	// lookups for a single game tick instead of issuing them serially. Only one session may
+	// be active for a provider at a time; a second call before the first is Close'd returns
+	// ErrSessionAlreadyRunning.
+	StartSession(ctx context.Context, concurrency int) (*TraceSession, error)
+}
+
+// StartSessionFor returns a TraceSession for provider. If provider implements
+// SessionTraceProvider it is used directly so a cannon/asterisc-backed provider can
+// parallelize trace generation across disjoint step boundaries. Otherwise the session's
+// workers call provider's plain Get/GetStepData directly, which suits providers cheap
+// enough that they need no pool of their own (alphabet, output-root). Each such session
+// gets its own running flag, so this path never returns ErrSessionAlreadyRunning.
+func StartSessionFor(ctx context.Context, provider TraceProvider, concurrency int) (*TraceSession, error) {
+	if sp, ok := provider.(SessionTraceProvider); ok {
+		return sp.StartSession(ctx, concurrency)
+	}
+	return NewTraceSession(ctx, concurrency, new(atomic.Bool), func(ctx context.Context, req TraceRequest) TraceResult {
+		if req.IsStep {
+			prestate, proofData, preimageData, err := provider.GetStepData(ctx, req.Pos)
+			return TraceResult{Prestate: prestate, ProofData: proofData, PreimageData: preimageData, Err: err}
+		}
+		value, err := provider.Get(ctx, req.Pos)
+		return TraceResult{Value: value, Err: err}
+	})
+}
+
+// TraceRequest describes a single lookup to submit to a TraceSession. Set IsStep to request
+// GetStepData instead of Get.
+type TraceRequest struct {
+	Pos    Position
+	IsStep bool
+}
+
+// TraceResult is delivered on a TraceRequest's result channel once a TraceSession worker has
+// serviced it. Value is only populated for a Get request and Prestate/ProofData/PreimageData
+// only for a GetStepData request (see TraceRequest.IsStep).
+type TraceResult struct {
+	Value        common.Hash
+	Prestate     []byte
+	ProofData    []byte
+	PreimageData *PreimageOracleData
+	Err          error
+}
+
+// TraceSessionFunc services a single TraceRequest on behalf of a TraceProvider. It must be
+// safe to call concurrently from multiple workers; a provider with a single underlying VM
+// snapshot should serialize internally so it still only services one request per worker.
+type TraceSessionFunc func(ctx context.Context, req TraceRequest) TraceResult
+
+// traceSessionJob pairs a submitted TraceRequest with the channel its TraceResult is
+// delivered on.
+type traceSessionJob struct {
+	req    TraceRequest
+	result chan TraceResult
+}
+
+// TraceSession multiplexes Get and GetStepData calls to a TraceProvider over a bounded
+// worker pool. Providers that are cheap to query (alphabet, output-root) get one from
+// StartSessionFor without implementing SessionTraceProvider; a provider backed by a VM
+// (cannon, asterisc) can implement SessionTraceProvider to parallelize trace generation
+// across disjoint step boundaries while still only running one VM snapshot per worker.
+type TraceSession struct {
+	ctx       context.Context
+	requests  chan traceSessionJob
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	running   *atomic.Bool
+}
+
+// NewTraceSession starts concurrency workers servicing work and marks running as in-use for
+// the lifetime of the session. running should be a field on the owning TraceProvider so a
+// second call to StartSession can detect that a session is already active and return
+// ErrSessionAlreadyRunning instead of starting a competing worker pool.
+func NewTraceSession(ctx context.Context, concurrency int, running *atomic.Bool, work TraceSessionFunc) (*TraceSession, error) {
+	if !running.CompareAndSwap(false, true) {
+		return nil, ErrSessionAlreadyRunning
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	s := &TraceSession{
+		ctx:      ctx,
+		requests: make(chan traceSessionJob),
+		done:     make(chan struct{}),
+		running:  running,
+	}
+	s.wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go s.worker(ctx, work)
+	}
+	return s, nil
+}
+
+// worker services requests until the session is closed or ctx is cancelled, so a caller
+// that cancels ctx mid-tick doesn't have to wait for every in-flight request to drain.
+func (s *TraceSession) worker(ctx context.Context, work TraceSessionFunc) {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.done:
+			return
+		case job := <-s.requests:
+			job.result <- work(ctx, job.req)
+		}
+	}
+}
+
+// Submit queues req for processing by a worker and returns a channel that receives exactly
+// one TraceResult. Callers fan-in the returned channels to collect batched lookups. Submit
+// returns ErrSessionClosed instead of sending if the session has already been, or is
+// concurrently being, closed, and returns ctx's error if ctx is cancelled instead of
+// blocking forever waiting for a worker that has already exited because of that same
+// cancellation (workers also select on ctx.Done(), see worker).
+func (s *TraceSession) Submit(req TraceRequest) (<-chan TraceResult, error) {
+	result := make(chan TraceResult, 1)
+	select {
+	case <-s.done:
+		return nil, ErrSessionClosed
+	case <-s.ctx.Done():
+		return nil, s.ctx.Err()
+	default:
+	}
+	select {
+	case s.requests <- traceSessionJob{req: req, result: result}:
+		return result, nil
+	case <-s.done:
+		return nil, ErrSessionClosed
+	case <-s.ctx.Done():
+		return nil, s.ctx.Err()
+	}
+}
+
+// Close stops accepting new requests, waits for in-flight workers to drain and marks the
+// session as no longer running so a subsequent StartSession call succeeds. Close is
+// idempotent and safe to call concurrently with Submit.
+func (s *TraceSession) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.wg.Wait()
+		s.running.Store(false)
+	})
+}
+
+// GetMany resolves every request in requests using a single TraceSession of the given
+// concurrency, fanning the per-request results back in, in the order of requests. It lets a
+// caller resolve a game tick's positions as one batch instead of calling
+// TraceProvider.Get/GetStepData serially.
+func GetMany(ctx context.Context, provider TraceProvider, concurrency int, requests []TraceRequest) ([]TraceResult, error) {
+	session, err := StartSessionFor(ctx, provider, concurrency)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	pending := make([]<-chan TraceResult, len(requests))
+	for i, req := range requests {
+		ch, err := session.Submit(req)
+		if err != nil {
+			return nil, err
+		}
+		pending[i] = ch
+	}
+
+	results := make([]TraceResult, len(requests))
+	for i, ch := range pending {
+		select {
+		case results[i] = <-ch:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return results, nil
+}
+
 // ClaimData is the core of a claim. It must be unique inside a specific game.
 type ClaimData struct {
 	Value common.Hash